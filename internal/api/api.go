@@ -2,13 +2,19 @@ package api
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"time"
 
 	"journey/internal/api/spec"
+	"journey/internal/authz"
+	"journey/internal/mailer/mailpit"
 	"journey/internal/pgstore"
+	"journey/internal/webhooks"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
@@ -19,26 +25,66 @@ import (
 )
 
 type mailer interface {
-	SendConfirmTripEmailToTripOwner(tripID uuid.UUID) error
+	Enqueue(ctx context.Context, job mailpit.Job) error
+	FailedMessages(ctx context.Context) ([]pgstore.OutboxMessage, error)
 }
 
 type store interface {
 	CreateTrip(context.Context, *pgxpool.Pool, spec.CreateTripRequest) (uuid.UUID, error)
+	DeleteTrip(ctx context.Context, tripID uuid.UUID) error
 	GetParticipant(ctx context.Context, participantID uuid.UUID) (pgstore.Participant, error)
 	ConfirmParticipant(ctx context.Context, participantID uuid.UUID) error
 	GetTrip(ctx context.Context, tripID uuid.UUID) (pgstore.Trip, error)
 	UpdateTrip(ctx context.Context, body pgstore.UpdateTripParams) error
+	GetParticipants(ctx context.Context, tripID uuid.UUID) ([]pgstore.Participant, error)
 	GetTripActivities(ctx context.Context, tripID uuid.UUID) ([]pgstore.Activity, error)
 	CreateActivity(ctx context.Context, arg pgstore.CreateActivityParams) (uuid.UUID, error)
 	GetTripLinks(ctx context.Context, tripID uuid.UUID) ([]pgstore.Link, error)
+	CreateInvite(ctx context.Context, arg pgstore.CreateInviteParams) (uuid.UUID, error)
+	GetInviteByToken(ctx context.Context, token string) (pgstore.Invite, error)
+	CreateInviteParticipant(ctx context.Context, arg pgstore.CreateInviteParticipantParams) (uuid.UUID, error)
+	DeleteParticipant(ctx context.Context, participantID uuid.UUID) error
+	ClaimInvite(ctx context.Context, id uuid.UUID) (int64, error)
+	SetInviteParticipant(ctx context.Context, arg pgstore.SetInviteParticipantParams) error
+	RevertInviteAcceptance(ctx context.Context, id uuid.UUID) error
 }
 
 type API struct {
-	store     store
-	logger    *zap.Logger
-	validator *validator.Validate
-	pool      *pgxpool.Pool
-	mailer    mailer
+	store          store
+	logger         *zap.Logger
+	validator      *validator.Validate
+	pool           *pgxpool.Pool
+	mailer         mailer
+	authz          authz.Authz
+	webhooks       webhooks.Publisher
+	webhooksWorker webhooks.Worker
+	adminKey       string
+}
+
+// subjectFromRequest extracts the calling subject's id. Until the API grows
+// real authentication, callers identify themselves via the X-Subject-ID
+// header (the owner id handed back from PostTrips, or a participant id).
+func subjectFromRequest(r *http.Request) (uuid.UUID, error) {
+	return uuid.Parse(r.Header.Get("X-Subject-ID"))
+}
+
+// authorize checks that the calling subject holds permission on trip,
+// returning an error if they don't (or can't be identified).
+func (api *API) authorize(r *http.Request, permission authz.Permission, trip uuid.UUID) error {
+	subject, err := subjectFromRequest(r)
+	if err != nil {
+		return err
+	}
+
+	ok, err := api.authz.Check(r.Context(), subject, permission, trip)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("not allowed")
+	}
+
+	return nil
 }
 
 // Confirms a participant on a trip.
@@ -49,6 +95,13 @@ func (api *API) PatchParticipantsParticipantIDConfirm(w http.ResponseWriter, r *
 		return spec.PatchParticipantsParticipantIDConfirmJSON400Response(spec.Error{Message: "uuid inválido"})
 	}
 
+	subject, err := subjectFromRequest(r)
+	if err != nil || subject != id {
+		return spec.PatchParticipantsParticipantIDConfirmJSON400Response(
+			spec.Error{Message: "not allowed to confirm this participant"},
+		)
+	}
+
 	participant, err := api.store.GetParticipant(r.Context(), id)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -75,12 +128,58 @@ func (api *API) PatchParticipantsParticipantIDConfirm(w http.ResponseWriter, r *
 		)
 	}
 
+	if err := api.webhooks.Publish(r.Context(), webhooks.EventParticipantConfirmed, participant.TripID, map[string]string{
+		"participant_id": participantID,
+		"trip_id":        participant.TripID.String(),
+	}); err != nil {
+		api.logger.Error("failed to publish participant.confirmed event", zap.Error(err))
+	}
+
 	return spec.PatchParticipantsParticipantIDConfirmJSON204Response(nil)
 }
 
-func NewAPI(pool *pgxpool.Pool, logger *zap.Logger, mailer mailer) API {
+// authorizeAdmin checks that the caller supplied the configured admin key,
+// gating the admin endpoints below that aren't scoped to a single trip and so
+// can't be checked through authz.Check.
+func (api *API) authorizeAdmin(r *http.Request) error {
+	provided := r.Header.Get("X-Admin-Key")
+	if api.adminKey == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(api.adminKey)) != 1 {
+		return errors.New("not allowed")
+	}
+	return nil
+}
+
+// Lists outbox messages that exhausted their delivery retries.
+// (GET /admin/outbox/failed)
+func (api *API) AdminGetOutboxFailed(w http.ResponseWriter, r *http.Request) {
+	if err := api.authorizeAdmin(r); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	messages, err := api.mailer.FailedMessages(r.Context())
+	if err != nil {
+		api.logger.Error("failed to list failed outbox messages", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(messages); err != nil {
+		api.logger.Error("failed to encode failed outbox messages", zap.Error(err))
+	}
+}
+
+// NewAPI builds an API backed by pool. adminKey (the ADMIN_API_KEY config) is
+// compared against the X-Admin-Key header on the admin inspection endpoints;
+// those endpoints are never reachable if it's left empty.
+func NewAPI(pool *pgxpool.Pool, logger *zap.Logger, mailer mailer, adminKey string) API {
 	validator := validator.New(validator.WithRequiredStructEnabled())
-	return API{pgstore.New(pool), logger, validator, pool, mailer}
+	return API{
+		pgstore.New(pool), logger, validator, pool, mailer,
+		authz.NewAuthz(pool), webhooks.NewPublisher(pool), webhooks.NewWorker(pool, logger),
+		adminKey,
+	}
 }
 
 // Create a new trip
@@ -95,21 +194,49 @@ func (api *API) PostTrips(w http.ResponseWriter, r *http.Request) *spec.Response
 		return spec.PostTripsJSON400Response(spec.Error{Message: "invalid input: " + err.Error()})
 	}
 
-	tripID, err := api.store.CreateTrip(r.Context(), api.pool, body)
+	subject, err := subjectFromRequest(r)
+	if err != nil {
+		return spec.PostTripsJSON400Response(spec.Error{Message: "X-Subject-ID header is required"})
+	}
 
-	go func() {
-		if err := api.mailer.SendConfirmTripEmailToTripOwner(tripID); err != nil {
-			api.logger.Error(
-				"failed to send email on PostTrips",
-				zap.Error(err),
-				zap.String("trip_id", tripID.String()))
-		}
-	}()
+	tripID, err := api.store.CreateTrip(r.Context(), api.pool, body)
 	if err != nil {
 		api.logger.Error("failed to create trip", zap.Error(err))
 		return spec.PostTripsJSON400Response(spec.Error{Message: "something went wrong, try again"})
 	}
 
+	// Unlike the mail/webhook side effects below, a failed grant isn't
+	// best-effort: without the owner relation every future authz.Check for
+	// this trip comes back empty, so the creator could never view or edit a
+	// trip that was otherwise created successfully. Trip creation isn't
+	// idempotent, so leaving the row in place would also mean every retry
+	// the caller makes on our own error message creates another orphan —
+	// compensate by deleting it instead.
+	if err := api.authz.Grant(r.Context(), tripID, subject, authz.RelationOwner); err != nil {
+		api.logger.Error("failed to grant owner relation", zap.Error(err), zap.String("trip_id", tripID.String()))
+		if delErr := api.store.DeleteTrip(r.Context(), tripID); delErr != nil {
+			api.logger.Error("failed to delete orphaned trip after grant failure", zap.Error(delErr), zap.String("trip_id", tripID.String()))
+		}
+		return spec.PostTripsJSON400Response(spec.Error{Message: "something went wrong, try again"})
+	}
+
+	if err := api.mailer.Enqueue(r.Context(), mailpit.Job{
+		IdempotencyKey: tripID.String() + ":owner_confirm",
+		TripID:         tripID,
+		Kind:           mailpit.JobKindConfirmTripOwner,
+	}); err != nil {
+		api.logger.Error(
+			"failed to enqueue confirm trip email on PostTrips",
+			zap.Error(err),
+			zap.String("trip_id", tripID.String()))
+	}
+
+	if err := api.webhooks.Publish(r.Context(), webhooks.EventTripCreated, tripID, map[string]string{
+		"trip_id": tripID.String(),
+	}); err != nil {
+		api.logger.Error("failed to publish trip.created event", zap.Error(err), zap.String("trip_id", tripID.String()))
+	}
+
 	return spec.PostTripsJSON201Response(spec.CreateTripResponse{TripID: tripID.String()})
 }
 
@@ -122,6 +249,10 @@ func (api *API) GetTripsTripID(w http.ResponseWriter, r *http.Request, tripID st
 		return spec.GetTripsTripIDJSON400Response(spec.Error{Message: "invalid trip id"})
 	}
 
+	if err := api.authorize(r, authz.PermissionView, id); err != nil {
+		return spec.GetTripsTripIDJSON400Response(spec.Error{Message: "not allowed to view this trip"})
+	}
+
 	trip, err := api.store.GetTrip(r.Context(), id)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -151,6 +282,10 @@ func (api *API) PutTripsTripID(w http.ResponseWriter, r *http.Request, tripID st
 		return spec.PutTripsTripIDJSON400Response(spec.Error{Message: "invalid trip id"})
 	}
 
+	if err := api.authorize(r, authz.PermissionEdit, id); err != nil {
+		return spec.PutTripsTripIDJSON400Response(spec.Error{Message: "not allowed to edit this trip"})
+	}
+
 	trip, err := api.store.GetTrip(r.Context(), id)
 
 	if err != nil {
@@ -183,6 +318,12 @@ func (api *API) PutTripsTripID(w http.ResponseWriter, r *http.Request, tripID st
 
 	}
 
+	if err := api.webhooks.Publish(r.Context(), webhooks.EventTripUpdated, id, map[string]string{
+		"trip_id": tripID,
+	}); err != nil {
+		api.logger.Error("failed to publish trip.updated event", zap.Error(err), zap.String("trip_id", tripID))
+	}
+
 	return spec.PutTripsTripIDJSON204Response(nil)
 }
 
@@ -195,6 +336,10 @@ func (api *API) GetTripsTripIDActivities(w http.ResponseWriter, r *http.Request,
 		return spec.GetTripsTripIDActivitiesJSON400Response(spec.Error{Message: "invalid trip id"})
 	}
 
+	if err := api.authorize(r, authz.PermissionView, id); err != nil {
+		return spec.GetTripsTripIDActivitiesJSON400Response(spec.Error{Message: "not allowed to view this trip"})
+	}
+
 	activities, err := api.store.GetTripActivities(r.Context(), id)
 	if err != nil {
 		api.logger.Error("failed to get trip activities", zap.Error(err), zap.String("trip_id", tripID))
@@ -243,6 +388,10 @@ func (api *API) PostTripsTripIDActivities(w http.ResponseWriter, r *http.Request
 		return spec.PostTripsTripIDActivitiesJSON400Response(spec.Error{Message: "invalid trip id"})
 	}
 
+	if err := api.authorize(r, authz.PermissionEdit, id); err != nil {
+		return spec.PostTripsTripIDActivitiesJSON400Response(spec.Error{Message: "not allowed to edit this trip"})
+	}
+
 	var body spec.CreateActivityRequest
 
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
@@ -267,19 +416,260 @@ func (api *API) PostTripsTripIDActivities(w http.ResponseWriter, r *http.Request
 		return spec.PostTripsTripIDActivitiesJSON400Response(spec.Error{Message: "something went wrong, try again"})
 	}
 
+	if err := api.webhooks.Publish(r.Context(), webhooks.EventActivityCreated, id, map[string]string{
+		"trip_id":     tripID,
+		"activity_id": activityID.String(),
+	}); err != nil {
+		api.logger.Error("failed to publish activity.created event", zap.Error(err), zap.String("trip_id", tripID))
+	}
+
 	return spec.PostTripsTripIDActivitiesJSON201Response(spec.CreateActivityResponse{ActivityID: activityID.String()})
 }
 
 // Confirm a trip and send e-mail invitations.
 // (GET /trips/{tripId}/confirm)
 func (api *API) GetTripsTripIDConfirm(w http.ResponseWriter, r *http.Request, tripID string) *spec.Response {
-	panic("not implemented") // TODO: Implement
+	id, err := uuid.Parse(tripID)
+	if err != nil {
+		api.logger.Error("failed to parse trip id", zap.Error(err), zap.String("trip_id", tripID))
+		return spec.GetTripsTripIDConfirmJSON400Response(spec.Error{Message: "invalid trip id"})
+	}
+
+	if err := api.authorize(r, authz.PermissionEdit, id); err != nil {
+		return spec.GetTripsTripIDConfirmJSON400Response(spec.Error{Message: "not allowed to confirm this trip"})
+	}
+
+	trip, err := api.store.GetTrip(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return spec.GetTripsTripIDConfirmJSON400Response(spec.Error{Message: "trip not found"})
+		}
+		api.logger.Error("failed to get trip", zap.Error(err), zap.String("trip_id", tripID))
+		return spec.GetTripsTripIDConfirmJSON400Response(spec.Error{Message: "something went wrong, try again"})
+	}
+
+	if trip.IsConfirmed {
+		return spec.GetTripsTripIDConfirmJSON400Response(spec.Error{Message: "trip já confirmada"})
+	}
+
+	if err := api.store.UpdateTrip(r.Context(), pgstore.UpdateTripParams{
+		ID:          id,
+		Destination: trip.Destination,
+		StartsAt:    trip.StartsAt,
+		EndsAt:      trip.EndsAt,
+		IsConfirmed: true,
+	}); err != nil {
+		api.logger.Error("failed to confirm trip", zap.Error(err), zap.String("trip_id", tripID))
+		return spec.GetTripsTripIDConfirmJSON400Response(spec.Error{Message: "something went wrong, try again"})
+	}
+
+	participants, err := api.store.GetParticipants(r.Context(), id)
+	if err != nil {
+		api.logger.Error("failed to get trip participants", zap.Error(err), zap.String("trip_id", tripID))
+		return spec.GetTripsTripIDConfirmJSON400Response(spec.Error{Message: "something went wrong, try again"})
+	}
+
+	for _, participant := range participants {
+		if err := api.mailer.Enqueue(r.Context(), mailpit.Job{
+			IdempotencyKey: participant.ID.String() + ":trip_confirmed",
+			TripID:         id,
+			Kind:           mailpit.JobKindTripConfirmed,
+			ParticipantID:  participant.ID,
+			Email:          participant.Email,
+		}); err != nil {
+			api.logger.Error(
+				"failed to enqueue trip confirmed email",
+				zap.Error(err),
+				zap.String("trip_id", tripID),
+				zap.String("participant_id", participant.ID.String()),
+			)
+		}
+	}
+
+	if err := api.webhooks.Publish(r.Context(), webhooks.EventTripConfirmed, id, map[string]string{
+		"trip_id": tripID,
+	}); err != nil {
+		api.logger.Error("failed to publish trip.confirmed event", zap.Error(err), zap.String("trip_id", tripID))
+	}
+
+	return spec.GetTripsTripIDConfirmJSON204Response(nil)
 }
 
 // Invite someone to the trip.
 // (POST /trips/{tripId}/invites)
 func (api *API) PostTripsTripIDInvites(w http.ResponseWriter, r *http.Request, tripID string) *spec.Response {
-	panic("not implemented") // TODO: Implement
+	id, err := uuid.Parse(tripID)
+	if err != nil {
+		return spec.PostTripsTripIDInvitesJSON400Response(spec.Error{Message: "invalid trip id"})
+	}
+
+	if err := api.authorize(r, authz.PermissionInvite, id); err != nil {
+		return spec.PostTripsTripIDInvitesJSON400Response(spec.Error{Message: "not allowed to invite to this trip"})
+	}
+
+	var body spec.InviteParticipantRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return spec.PostTripsTripIDInvitesJSON400Response(spec.Error{Message: "invalid JSON"})
+	}
+
+	if err := api.validator.Struct(body); err != nil {
+		return spec.PostTripsTripIDInvitesJSON400Response(spec.Error{Message: "invalid input: " + err.Error()})
+	}
+
+	token, err := newInviteToken()
+	if err != nil {
+		api.logger.Error("failed to generate invite token", zap.Error(err))
+		return spec.PostTripsTripIDInvitesJSON400Response(spec.Error{Message: "something went wrong, try again"})
+	}
+
+	inviteID, err := api.store.CreateInvite(r.Context(), pgstore.CreateInviteParams{
+		TripID:       id,
+		Token:        token,
+		InvitedEmail: body.Email,
+		ExpiresAt:    time.Now().Add(inviteTTL),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return spec.PostTripsTripIDInvitesJSON400Response(spec.Error{Message: "trip not found"})
+		}
+		api.logger.Error("failed to create invite", zap.Error(err), zap.String("trip_id", tripID))
+		return spec.PostTripsTripIDInvitesJSON400Response(spec.Error{Message: "something went wrong, try again"})
+	}
+
+	if err := api.mailer.Enqueue(r.Context(), mailpit.Job{
+		IdempotencyKey: inviteID.String() + ":invite",
+		TripID:         id,
+		Kind:           mailpit.JobKindInvite,
+		Email:          body.Email,
+		InviteToken:    token,
+	}); err != nil {
+		api.logger.Error(
+			"failed to enqueue invite email",
+			zap.Error(err),
+			zap.String("trip_id", tripID),
+			zap.String("invite_id", inviteID.String()),
+		)
+	}
+
+	if err := api.webhooks.Publish(r.Context(), webhooks.EventParticipantInvited, id, map[string]string{
+		"trip_id":   tripID,
+		"invite_id": inviteID.String(),
+		"email":     body.Email,
+	}); err != nil {
+		api.logger.Error("failed to publish participant.invited event", zap.Error(err), zap.String("trip_id", tripID))
+	}
+
+	return spec.PostTripsTripIDInvitesJSON201Response(spec.InviteParticipantResponse{InviteID: inviteID.String()})
+}
+
+// Resolves an invite token, e.g. to render an acceptance page.
+// (GET /invites/{token})
+func (api *API) GetInvitesToken(w http.ResponseWriter, r *http.Request, token string) {
+	invite, err := api.store.GetInviteByToken(r.Context(), token)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		api.logger.Error("failed to get invite by token", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(invite)
+}
+
+// Accepts an invite, confirming the invitee as a trip participant.
+// (POST /invites/{token}/accept)
+func (api *API) PostInvitesTokenAccept(w http.ResponseWriter, r *http.Request, token string) {
+	invite, err := api.store.GetInviteByToken(r.Context(), token)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		api.logger.Error("failed to get invite by token", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if invite.Status != "pending" || invite.ExpiresAt.Before(time.Now()) {
+		w.WriteHeader(http.StatusGone)
+		return
+	}
+
+	// ClaimInvite is the only write gated on status = 'pending', so it's the
+	// one source of truth for who won the race: two concurrent accepts can
+	// both pass the check above, but only one of them claims the invite here.
+	rows, err := api.store.ClaimInvite(r.Context(), invite.ID)
+	if err != nil {
+		api.logger.Error("failed to claim invite", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if rows == 0 {
+		w.WriteHeader(http.StatusGone)
+		return
+	}
+
+	participantID, err := api.store.CreateInviteParticipant(r.Context(), pgstore.CreateInviteParticipantParams{
+		TripID: invite.TripID,
+		Email:  invite.InvitedEmail,
+	})
+	if err != nil {
+		api.logger.Error("failed to create invite participant", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if err := api.store.SetInviteParticipant(r.Context(), pgstore.SetInviteParticipantParams{
+		ID:            invite.ID,
+		ParticipantID: participantID,
+	}); err != nil {
+		api.logger.Error("failed to set invite participant", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	// As in PostTrips, a failed grant is access-control state, not a
+	// best-effort notification: without it the new participant could never
+	// view the trip they just joined. The invite is already claimed and the
+	// participant row already committed, so just logging here would burn the
+	// invite (it can't be re-claimed) and strand an inaccessible participant
+	// — revert both so the invite goes back to being redeemable.
+	if err := api.authz.Grant(r.Context(), invite.TripID, participantID, authz.RelationParticipant); err != nil {
+		api.logger.Error("failed to grant participant relation", zap.Error(err), zap.String("trip_id", invite.TripID.String()))
+		if revertErr := api.store.RevertInviteAcceptance(r.Context(), invite.ID); revertErr != nil {
+			api.logger.Error("failed to revert invite acceptance after grant failure", zap.Error(revertErr), zap.String("invite_id", invite.ID.String()))
+		}
+		if delErr := api.store.DeleteParticipant(r.Context(), participantID); delErr != nil {
+			api.logger.Error("failed to delete orphaned participant after grant failure", zap.Error(delErr), zap.String("participant_id", participantID.String()))
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if err := api.webhooks.Publish(r.Context(), webhooks.EventParticipantConfirmed, invite.TripID, map[string]string{
+		"trip_id":        invite.TripID.String(),
+		"participant_id": participantID.String(),
+	}); err != nil {
+		api.logger.Error("failed to publish participant.confirmed event", zap.Error(err), zap.String("trip_id", invite.TripID.String()))
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// inviteTTL is how long an invite token stays valid before it must be
+// redeemed.
+const inviteTTL = 72 * time.Hour
+
+func newInviteToken() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(raw), nil
 }
 
 // Get a trip links.
@@ -290,6 +680,10 @@ func (api *API) GetTripsTripIDLinks(w http.ResponseWriter, r *http.Request, trip
 		return spec.GetTripsTripIDLinksJSON400Response(spec.Error{Message: "invalid trip id"})
 	}
 
+	if err := api.authorize(r, authz.PermissionView, id); err != nil {
+		return spec.GetTripsTripIDLinksJSON400Response(spec.Error{Message: "not allowed to view this trip"})
+	}
+
 	links, err := api.store.GetTripLinks(r.Context(), id)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -323,3 +717,145 @@ func (api *API) PostTripsTripIDLinks(w http.ResponseWriter, r *http.Request, tri
 func (api *API) GetTripsTripIDParticipants(w http.ResponseWriter, r *http.Request, tripID string) *spec.Response {
 	panic("not implemented") // TODO: Implement
 }
+
+type createWebhookSubscriptionRequest struct {
+	URL    string   `json:"url" validate:"required,url"`
+	Secret string   `json:"secret" validate:"required"`
+	Events []string `json:"events" validate:"required,min=1"`
+}
+
+// Subscribes to trip lifecycle events for a trip.
+// (POST /trips/{tripId}/webhooks)
+func (api *API) PostTripsTripIDWebhooks(w http.ResponseWriter, r *http.Request, tripID string) {
+	id, err := uuid.Parse(tripID)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if err := api.authorize(r, authz.PermissionEdit, id); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	var body createWebhookSubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if err := api.validator.Struct(body); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	subscriptionID, err := api.webhooks.Subscribe(r.Context(), id, body.URL, body.Secret, body.Events)
+	if err != nil {
+		api.logger.Error("failed to create webhook subscription", zap.Error(err), zap.String("trip_id", tripID))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(map[string]string{"subscription_id": subscriptionID.String()})
+}
+
+// Lists webhook subscriptions for a trip.
+// (GET /trips/{tripId}/webhooks)
+func (api *API) GetTripsTripIDWebhooks(w http.ResponseWriter, r *http.Request, tripID string) {
+	id, err := uuid.Parse(tripID)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if err := api.authorize(r, authz.PermissionView, id); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	subscriptions, err := api.webhooks.ListForTrip(r.Context(), id)
+	if err != nil {
+		api.logger.Error("failed to list webhook subscriptions", zap.Error(err), zap.String("trip_id", tripID))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(subscriptions)
+}
+
+// Removes a webhook subscription from a trip.
+// (DELETE /trips/{tripId}/webhooks/{subscriptionId})
+func (api *API) DeleteTripsTripIDWebhooksSubscriptionID(w http.ResponseWriter, r *http.Request, tripID string, subscriptionID string) {
+	id, err := uuid.Parse(tripID)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	subID, err := uuid.Parse(subscriptionID)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if err := api.authorize(r, authz.PermissionEdit, id); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	if err := api.webhooks.Unsubscribe(r.Context(), subID); err != nil {
+		api.logger.Error("failed to delete webhook subscription", zap.Error(err), zap.String("subscription_id", subscriptionID))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Lists webhook deliveries that exhausted their retries.
+// (GET /admin/webhooks/failed)
+func (api *API) AdminGetWebhooksFailed(w http.ResponseWriter, r *http.Request) {
+	if err := api.authorizeAdmin(r); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	deliveries, err := api.webhooksWorker.FailedDeliveries(r.Context())
+	if err != nil {
+		api.logger.Error("failed to list failed webhook deliveries", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(deliveries); err != nil {
+		api.logger.Error("failed to encode failed webhook deliveries", zap.Error(err))
+	}
+}
+
+// Resets a failed webhook delivery back to pending so it is retried
+// immediately.
+// (POST /admin/webhooks/deliveries/{deliveryId}/redeliver)
+func (api *API) AdminPostWebhooksDeliveriesDeliveryIDRedeliver(w http.ResponseWriter, r *http.Request, deliveryID string) {
+	if err := api.authorizeAdmin(r); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	id, err := uuid.Parse(deliveryID)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if err := api.webhooksWorker.Redeliver(r.Context(), id); err != nil {
+		api.logger.Error("failed to redeliver webhook delivery", zap.Error(err), zap.String("delivery_id", deliveryID))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
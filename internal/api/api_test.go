@@ -0,0 +1,154 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"journey/internal/api/spec"
+	"journey/internal/pgstore"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type fakeInviteStore struct {
+	invite       pgstore.Invite
+	inviteErr    error
+	claimRows    int64
+	claimErr     error
+	claimCalls   int
+	createCalled bool
+}
+
+func (f *fakeInviteStore) CreateTrip(context.Context, *pgxpool.Pool, spec.CreateTripRequest) (uuid.UUID, error) {
+	return uuid.Nil, nil
+}
+func (f *fakeInviteStore) DeleteTrip(context.Context, uuid.UUID) error { return nil }
+func (f *fakeInviteStore) GetParticipant(context.Context, uuid.UUID) (pgstore.Participant, error) {
+	return pgstore.Participant{}, nil
+}
+func (f *fakeInviteStore) ConfirmParticipant(context.Context, uuid.UUID) error { return nil }
+func (f *fakeInviteStore) GetTrip(context.Context, uuid.UUID) (pgstore.Trip, error) {
+	return pgstore.Trip{}, nil
+}
+func (f *fakeInviteStore) UpdateTrip(context.Context, pgstore.UpdateTripParams) error { return nil }
+func (f *fakeInviteStore) GetParticipants(context.Context, uuid.UUID) ([]pgstore.Participant, error) {
+	return nil, nil
+}
+func (f *fakeInviteStore) GetTripActivities(context.Context, uuid.UUID) ([]pgstore.Activity, error) {
+	return nil, nil
+}
+func (f *fakeInviteStore) CreateActivity(context.Context, pgstore.CreateActivityParams) (uuid.UUID, error) {
+	return uuid.Nil, nil
+}
+func (f *fakeInviteStore) GetTripLinks(context.Context, uuid.UUID) ([]pgstore.Link, error) {
+	return nil, nil
+}
+func (f *fakeInviteStore) CreateInvite(context.Context, pgstore.CreateInviteParams) (uuid.UUID, error) {
+	return uuid.Nil, nil
+}
+func (f *fakeInviteStore) GetInviteByToken(context.Context, string) (pgstore.Invite, error) {
+	return f.invite, f.inviteErr
+}
+func (f *fakeInviteStore) CreateInviteParticipant(context.Context, pgstore.CreateInviteParticipantParams) (uuid.UUID, error) {
+	f.createCalled = true
+	return uuid.New(), nil
+}
+func (f *fakeInviteStore) DeleteParticipant(context.Context, uuid.UUID) error { return nil }
+func (f *fakeInviteStore) ClaimInvite(context.Context, uuid.UUID) (int64, error) {
+	f.claimCalls++
+	return f.claimRows, f.claimErr
+}
+func (f *fakeInviteStore) SetInviteParticipant(context.Context, pgstore.SetInviteParticipantParams) error {
+	return nil
+}
+func (f *fakeInviteStore) RevertInviteAcceptance(context.Context, uuid.UUID) error { return nil }
+
+func newInviteAcceptRequest(token string) (*httptest.ResponseRecorder, *http.Request) {
+	r := httptest.NewRequest(http.MethodPost, "/invites/"+token+"/accept", nil)
+	return httptest.NewRecorder(), r
+}
+
+// TestPostInvitesTokenAcceptClaimRace exercises the claim-race branch called
+// out in review: ClaimInvite is the only write gated on status = 'pending',
+// so losing the race (rows == 0) must turn into 410 Gone without ever
+// creating a participant, even though the invite looked acceptable at the
+// GetInviteByToken check a moment earlier.
+func TestPostInvitesTokenAcceptClaimRace(t *testing.T) {
+	store := &fakeInviteStore{
+		invite: pgstore.Invite{
+			ID:        uuid.New(),
+			TripID:    uuid.New(),
+			Status:    "pending",
+			ExpiresAt: time.Now().Add(time.Hour),
+		},
+		claimRows: 0,
+	}
+	api := API{store: store, logger: zap.NewNop()}
+
+	w, r := newInviteAcceptRequest("some-token")
+	api.PostInvitesTokenAccept(w, r, "some-token")
+
+	if w.Code != http.StatusGone {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusGone)
+	}
+	if store.claimCalls != 1 {
+		t.Errorf("ClaimInvite calls = %d, want 1", store.claimCalls)
+	}
+	if store.createCalled {
+		t.Error("CreateInviteParticipant was called after losing the claim race")
+	}
+}
+
+// TestPostInvitesTokenAcceptExpired covers the expiry branch: an invite past
+// its ExpiresAt must be rejected before ever attempting ClaimInvite.
+func TestPostInvitesTokenAcceptExpired(t *testing.T) {
+	store := &fakeInviteStore{
+		invite: pgstore.Invite{
+			ID:        uuid.New(),
+			TripID:    uuid.New(),
+			Status:    "pending",
+			ExpiresAt: time.Now().Add(-time.Hour),
+		},
+	}
+	api := API{store: store, logger: zap.NewNop()}
+
+	w, r := newInviteAcceptRequest("some-token")
+	api.PostInvitesTokenAccept(w, r, "some-token")
+
+	if w.Code != http.StatusGone {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusGone)
+	}
+	if store.claimCalls != 0 {
+		t.Errorf("ClaimInvite calls = %d, want 0 for an already-expired invite", store.claimCalls)
+	}
+}
+
+// TestPostInvitesTokenAcceptAlreadyAccepted covers the same early-return
+// branch for an invite whose status was already flipped by a previous
+// accept, independent of expiry.
+func TestPostInvitesTokenAcceptAlreadyAccepted(t *testing.T) {
+	store := &fakeInviteStore{
+		invite: pgstore.Invite{
+			ID:        uuid.New(),
+			TripID:    uuid.New(),
+			Status:    "accepted",
+			ExpiresAt: time.Now().Add(time.Hour),
+		},
+	}
+	api := API{store: store, logger: zap.NewNop()}
+
+	w, r := newInviteAcceptRequest("some-token")
+	api.PostInvitesTokenAccept(w, r, "some-token")
+
+	if w.Code != http.StatusGone {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusGone)
+	}
+	if store.claimCalls != 0 {
+		t.Errorf("ClaimInvite calls = %d, want 0 for a non-pending invite", store.claimCalls)
+	}
+}
@@ -0,0 +1,86 @@
+// Package authz enforces who can read and write trip resources. Handlers
+// consult a single Authz instance instead of scattering ad-hoc ownership
+// checks across internal/api.
+package authz
+
+import (
+	"context"
+	"journey/internal/pgstore"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Relation is a fact granted to a subject on a trip (who they are to it).
+type Relation string
+
+const (
+	RelationOwner       Relation = "owner"
+	RelationParticipant Relation = "participant"
+	RelationViewer      Relation = "viewer"
+)
+
+// Permission is an action a handler wants to perform on a trip resource.
+type Permission string
+
+const (
+	PermissionView   Permission = "view"
+	PermissionEdit   Permission = "edit"
+	PermissionInvite Permission = "invite"
+)
+
+// policy maps each relation to the permissions it grants.
+var policy = map[Relation][]Permission{
+	RelationOwner:       {PermissionView, PermissionEdit, PermissionInvite},
+	RelationParticipant: {PermissionView},
+	RelationViewer:      {PermissionView},
+}
+
+func grants(relation Relation, permission Permission) bool {
+	for _, p := range policy[relation] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+type store interface {
+	CreateTripRelation(ctx context.Context, arg pgstore.CreateTripRelationParams) error
+	GetTripRelations(ctx context.Context, arg pgstore.GetTripRelationsParams) ([]string, error)
+}
+
+type Authz struct {
+	store store
+}
+
+func NewAuthz(pool *pgxpool.Pool) Authz {
+	return Authz{pgstore.New(pool)}
+}
+
+// Grant records that subject has relation on trip, e.g. the owner relation
+// created alongside a new trip, or the participant relation created when an
+// invite is accepted.
+func (a Authz) Grant(ctx context.Context, tripID uuid.UUID, subject uuid.UUID, relation Relation) error {
+	return a.store.CreateTripRelation(ctx, pgstore.CreateTripRelationParams{
+		TripID:    tripID,
+		SubjectID: subject,
+		Relation:  string(relation),
+	})
+}
+
+// Check reports whether subject holds permission on trip.
+func (a Authz) Check(ctx context.Context, subject uuid.UUID, permission Permission, trip uuid.UUID) (bool, error) {
+	relations, err := a.store.GetTripRelations(ctx, pgstore.GetTripRelationsParams{TripID: trip, SubjectID: subject})
+	if err != nil {
+		return false, err
+	}
+
+	for _, relation := range relations {
+		if grants(Relation(relation), permission) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
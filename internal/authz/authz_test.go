@@ -0,0 +1,98 @@
+package authz
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"journey/internal/pgstore"
+
+	"github.com/google/uuid"
+)
+
+func TestGrants(t *testing.T) {
+	tests := []struct {
+		relation   Relation
+		permission Permission
+		want       bool
+	}{
+		{RelationOwner, PermissionView, true},
+		{RelationOwner, PermissionEdit, true},
+		{RelationOwner, PermissionInvite, true},
+		{RelationParticipant, PermissionView, true},
+		{RelationParticipant, PermissionEdit, false},
+		{RelationViewer, PermissionView, true},
+		{RelationViewer, PermissionInvite, false},
+		{Relation("unknown"), PermissionView, false},
+	}
+
+	for _, tt := range tests {
+		if got := grants(tt.relation, tt.permission); got != tt.want {
+			t.Errorf("grants(%q, %q) = %v, want %v", tt.relation, tt.permission, got, tt.want)
+		}
+	}
+}
+
+type fakeStore struct {
+	relations []string
+	err       error
+}
+
+func (f fakeStore) CreateTripRelation(ctx context.Context, arg pgstore.CreateTripRelationParams) error {
+	return f.err
+}
+
+func (f fakeStore) GetTripRelations(ctx context.Context, arg pgstore.GetTripRelationsParams) ([]string, error) {
+	return f.relations, f.err
+}
+
+func TestCheck(t *testing.T) {
+	trip := uuid.New()
+	subject := uuid.New()
+
+	t.Run("allowed when a held relation grants the permission", func(t *testing.T) {
+		a := Authz{store: fakeStore{relations: []string{string(RelationParticipant)}}}
+
+		ok, err := a.Check(context.Background(), subject, PermissionView, trip)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !ok {
+			t.Fatal("expected Check to allow PermissionView for a participant")
+		}
+	})
+
+	t.Run("denied when no held relation grants the permission", func(t *testing.T) {
+		a := Authz{store: fakeStore{relations: []string{string(RelationParticipant)}}}
+
+		ok, err := a.Check(context.Background(), subject, PermissionEdit, trip)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if ok {
+			t.Fatal("expected Check to deny PermissionEdit for a participant")
+		}
+	})
+
+	t.Run("denied when subject holds no relations", func(t *testing.T) {
+		a := Authz{store: fakeStore{}}
+
+		ok, err := a.Check(context.Background(), subject, PermissionView, trip)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if ok {
+			t.Fatal("expected Check to deny a subject with no relations")
+		}
+	})
+
+	t.Run("propagates store errors", func(t *testing.T) {
+		wantErr := errors.New("boom")
+		a := Authz{store: fakeStore{err: wantErr}}
+
+		_, err := a.Check(context.Background(), subject, PermissionView, trip)
+		if !errors.Is(err, wantErr) {
+			t.Fatalf("expected store error to propagate, got %v", err)
+		}
+	})
+}
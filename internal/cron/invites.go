@@ -0,0 +1,101 @@
+// Package cron runs periodic background sweeps that don't belong behind an
+// HTTP handler, such as expiring stale invites and reminding trip owners
+// about invites that are about to expire.
+package cron
+
+import (
+	"context"
+	"journey/internal/mailer/mailpit"
+	"journey/internal/pgstore"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// checkInvitesInterval is how often the invite checker sweeps the table.
+const checkInvitesInterval = time.Hour
+
+type store interface {
+	ExpireInvites(ctx context.Context) error
+	GetInvitesExpiringSoon(ctx context.Context) ([]pgstore.GetInvitesExpiringSoonRow, error)
+	MarkInviteReminderSent(ctx context.Context, id uuid.UUID) error
+}
+
+type mailer interface {
+	Enqueue(ctx context.Context, job mailpit.Job) error
+}
+
+// InviteChecker periodically expires stale invites and reminds trip owners
+// about invites that are about to expire and still haven't been redeemed.
+type InviteChecker struct {
+	store  store
+	mailer mailer
+	logger *zap.Logger
+}
+
+func NewInviteChecker(pool *pgxpool.Pool, mailer mailer, logger *zap.Logger) InviteChecker {
+	return InviteChecker{pgstore.New(pool), mailer, logger}
+}
+
+// Run sweeps the invites table every checkInvitesInterval until ctx is
+// canceled.
+func (c InviteChecker) Run(ctx context.Context) {
+	ticker := time.NewTicker(checkInvitesInterval)
+	defer ticker.Stop()
+
+	c.checkInvites(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.checkInvites(ctx)
+		}
+	}
+}
+
+func (c InviteChecker) checkInvites(ctx context.Context) {
+	if err := c.store.ExpireInvites(ctx); err != nil {
+		c.logger.Error("cron: failed to expire invites", zap.Error(err))
+		return
+	}
+
+	invites, err := c.store.GetInvitesExpiringSoon(ctx)
+	if err != nil {
+		c.logger.Error("cron: failed to list invites expiring soon", zap.Error(err))
+		return
+	}
+
+	for _, invite := range invites {
+		if !invite.OwnerNotifyExpiry {
+			continue
+		}
+
+		job := mailpit.Job{
+			IdempotencyKey: invite.Invite.ID.String() + ":expiry_reminder",
+			TripID:         invite.Invite.TripID,
+			Kind:           mailpit.JobKindInviteExpiryReminder,
+			Email:          invite.Invite.InvitedEmail,
+		}
+
+		if err := c.mailer.Enqueue(ctx, job); err != nil {
+			c.logger.Error(
+				"cron: failed to enqueue invite expiry reminder",
+				zap.Error(err),
+				zap.String("invite_id", invite.Invite.ID.String()),
+			)
+			continue
+		}
+
+		if err := c.store.MarkInviteReminderSent(ctx, invite.Invite.ID); err != nil {
+			c.logger.Error(
+				"cron: failed to mark invite reminder sent",
+				zap.Error(err),
+				zap.String("invite_id", invite.Invite.ID.String()),
+			)
+		}
+	}
+}
@@ -2,99 +2,90 @@ package mailpit
 
 import (
 	"context"
-	"fmt"
 	"journey/internal/pgstore"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/wneessen/go-mail"
+	"go.uber.org/zap"
 )
 
+// defaultSendDeadline bounds how long dialing the SMTP connection for one
+// trip's batch may take. The sends over that connection are not bounded by
+// it.
+const defaultSendDeadline = 30 * time.Second
+
+// defaultShutdownGrace bounds how long Run waits for in-flight sends to
+// finish once its context is canceled.
+const defaultShutdownGrace = 30 * time.Second
+
 type store interface {
 	GetTrip(context.Context, uuid.UUID) (pgstore.Trip, error)
-	GetParticipants(context.Context, uuid.UUID) ([]pgstore.Participant, error)
+	EnqueueOutboxMessage(ctx context.Context, arg pgstore.EnqueueOutboxMessageParams) (uuid.UUID, error)
+	LockPendingMailOutboxMessages(ctx context.Context, limit int32) ([]pgstore.OutboxMessage, error)
+	RequeueStaleMailOutboxMessages(ctx context.Context, olderThan time.Time) error
+	MarkOutboxMessageSent(ctx context.Context, id uuid.UUID) error
+	RescheduleOutboxMessage(ctx context.Context, arg pgstore.RescheduleOutboxMessageParams) error
+	FailOutboxMessage(ctx context.Context, arg pgstore.FailOutboxMessageParams) error
+	GetFailedOutboxMessages(ctx context.Context) ([]pgstore.OutboxMessage, error)
+	GetTripLanguage(ctx context.Context, tripID uuid.UUID) (string, error)
+	GetParticipantLanguage(ctx context.Context, participantID uuid.UUID) (string, error)
 }
 
 type MailPit struct {
-	store store
-}
-
-func NewMailPit(pool *pgxpool.Pool) MailPit {
-	return MailPit{pgstore.New(pool)}
+	store         store
+	pool          *pgxpool.Pool
+	logger        *zap.Logger
+	publicBaseURL string
+	sendDeadline  time.Duration
+	shutdownGrace time.Duration
+	wg            *sync.WaitGroup
 }
 
-func (m MailPit) SendConfirmTripEmailToTripOwner(tripId uuid.UUID) error {
-	ctx := context.Background()
-	trip, err := m.store.GetTrip(ctx, tripId)
-	if err != nil {
-		return fmt.Errorf("mailpit: failed to get trip for SendConfirmTripEmailToTripOwner: %w", err)
-	}
-
-	msg := mail.NewMsg()
-	if err := msg.From("mailpit@journey.com"); err != nil {
-		return fmt.Errorf("mailpit: failed to set From in email for SendConfirmTripEmailToTripOwner: %w", err)
-	}
-
-	if err := msg.To(trip.OwnerEmail); err != nil {
-		return fmt.Errorf("mailpit: failed to set To in email for SendConfirmTripEmailToTripOwner: %w", err)
-	}
-
-	msg.Subject("Confirme sua viagem")
-
-	msg.SetBodyString(mail.TypeTextPlain, fmt.Sprintf(`
-    Olá, %s!
-    A sua viagem para %s que começa no dia %s precisa ser confirmada.
-    Clique no botão abaixo para confirmar.
-    `,
-		trip.OwnerName,
-		trip.Destination,
-		trip.StartsAt.Time.Format(time.DateOnly),
-	))
-
-	client, err := mail.NewClient("mailpit", mail.WithTLSPortPolicy(mail.NoTLS), mail.WithPort(1025))
-
-	if err != nil {
-		return fmt.Errorf("mailpit: failed to create mail client for SendConfirmTripEmailToTripOwner: %w", err)
+// NewMailPit builds a MailPit backed by pool. publicBaseURL (the
+// PUBLIC_BASE_URL config) is used to build links in rendered emails, e.g.
+// the trip confirmation button.
+func NewMailPit(pool *pgxpool.Pool, publicBaseURL string, logger *zap.Logger) MailPit {
+	return MailPit{
+		store:         pgstore.New(pool),
+		pool:          pool,
+		logger:        logger,
+		publicBaseURL: publicBaseURL,
+		sendDeadline:  defaultSendDeadline,
+		shutdownGrace: defaultShutdownGrace,
+		wg:            &sync.WaitGroup{},
 	}
-
-	if err := client.DialAndSend(msg); err != nil {
-		return fmt.Errorf("mailpit: failed to send email for SendConfirmTripEmailToTripOwner: %w", err)
-	}
-
-	return nil
 }
 
-func (m MailPit) SendTripConfirmedEmails(tripId uuid.UUID) error {
-	ctx := context.Background()
-	participants, err := m.store.GetParticipants(ctx, tripId)
-	if err != nil {
-		return fmt.Errorf("mailpit: failed to get trip participants for SendTripConfirmedEmails: %w", err)
-	}
-
-	client, err := mail.NewClient("mailpit", mail.WithTLSPortPolicy(mail.NoTLS), mail.WithPort(1025))
-	if err != nil {
-		return fmt.Errorf("mailpit: failed to create mail client for SendTripConfirmedEmails: %w", err)
-	}
-
-	for _, participant := range participants {
-		msg := mail.NewMsg()
-		if err := msg.From("mailpit@journey.com"); err != nil {
-			return fmt.Errorf("mailpit: failed to set From in email for SendTripConfirmedEmails: %w", err)
-		}
-
-		if err := msg.To(participant.Email); err != nil {
-			return fmt.Errorf("mailpit: failed to set To in email for SendTripConfirmedEmails: %w", err)
-		}
-
-		msg.Subject("Confirme sua viagem")
-
-		msg.SetBodyString(mail.TypeTextPlain, "Você deve confirmar a sua viagem")
-
-		if err := client.DialAndSend(msg); err != nil {
-			return fmt.Errorf("mailpit: failed to send email for SendTripConfirmedEmails: %w", err)
-		}
+// Run starts the outbox worker and blocks until ctx is canceled, at which
+// point it waits (up to its shutdownGrace) for sends already in flight to
+// finish before returning. In-flight sends are given drainCtx — a context
+// independent of ctx's cancellation — so the shutdown signal that stops the
+// poll loop doesn't also cancel the sends the grace period exists to let
+// finish.
+func (m MailPit) Run(ctx context.Context) {
+	worker := NewWorker(m)
+
+	drainCtx, cancelDrain := context.WithCancel(context.Background())
+	defer cancelDrain()
+	worker.drainCtx = drainCtx
+
+	workerDone := make(chan struct{})
+	go func() {
+		worker.Run(ctx)
+		close(workerDone)
+	}()
+	<-workerDone
+
+	drained := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+	case <-time.After(m.shutdownGrace):
 	}
-
-	return nil
 }
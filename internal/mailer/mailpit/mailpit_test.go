@@ -0,0 +1,64 @@
+package mailpit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func newTestMailPit(shutdownGrace time.Duration) MailPit {
+	return MailPit{
+		store:         &fakeStore{},
+		logger:        zap.NewNop(),
+		sendDeadline:  time.Second,
+		shutdownGrace: shutdownGrace,
+		wg:            &sync.WaitGroup{},
+	}
+}
+
+// TestRunDrainsBeforeShutdownGrace covers the select in Run's shutdown path:
+// when in-flight sends finish before shutdownGrace elapses, Run should
+// return as soon as they drain rather than waiting out the full grace
+// period.
+func TestRunDrainsBeforeShutdownGrace(t *testing.T) {
+	m := newTestMailPit(time.Second)
+	m.wg.Add(1)
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		m.wg.Done()
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	m.Run(ctx)
+	elapsed := time.Since(start)
+
+	if elapsed >= time.Second {
+		t.Errorf("Run took %s, expected to return once the in-flight send drained, well under the 1s grace", elapsed)
+	}
+}
+
+// TestRunReturnsAfterShutdownGraceExpires covers the other side of the same
+// select: a send that's still in flight once shutdownGrace elapses must not
+// block Run forever — it gives up on the drain and returns.
+func TestRunReturnsAfterShutdownGraceExpires(t *testing.T) {
+	grace := 20 * time.Millisecond
+	m := newTestMailPit(grace)
+	m.wg.Add(1) // deliberately never Done: simulates a send still in flight
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	m.Run(ctx)
+	elapsed := time.Since(start)
+
+	if elapsed < grace {
+		t.Errorf("Run returned after %s, expected to wait out the %s shutdown grace", elapsed, grace)
+	}
+}
@@ -0,0 +1,343 @@
+package mailpit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"journey/internal/mailtmpl"
+	"journey/internal/pgstore"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wneessen/go-mail"
+	"go.uber.org/zap"
+)
+
+// Job kinds understood by the outbox worker. Each kind maps to one case of
+// Worker.buildMessage below.
+const (
+	JobKindConfirmTripOwner     = "confirm_trip_owner"
+	JobKindTripConfirmed        = "trip_confirmed"
+	JobKindInvite               = "invite"
+	JobKindInviteExpiryReminder = "invite_expiry_reminder"
+)
+
+// backoffSchedule is the delay applied after the Nth failed attempt (0-indexed).
+// Once attempts reaches len(backoffSchedule) the message is marked failed and
+// requires manual redelivery.
+var backoffSchedule = []time.Duration{
+	30 * time.Second,
+	2 * time.Minute,
+	10 * time.Minute,
+}
+
+// staleProcessingAfter bounds how long a row may sit claimed (status =
+// 'processing') before the next poll assumes the goroutine that claimed it
+// is gone — crashed, killed, or wedged — and requeues it. It exceeds
+// sendDeadline, which bounds only the dial; the sends over that connection
+// are not bounded by it, so a batch still sending after this long is
+// requeued as well.
+const staleProcessingAfter = 5 * time.Minute
+
+// Job describes a single outbox message to be enqueued. IdempotencyKey must
+// be unique per logical send (e.g. "<trip_id>:owner_confirm" or
+// "<invite_id>:invite") so retried enqueues from handlers or the worker
+// itself never cause a duplicate send.
+type Job struct {
+	IdempotencyKey string
+	TripID         uuid.UUID
+	Kind           string
+	ParticipantID  uuid.UUID
+	Email          string
+	InviteToken    string
+	OwnerName      string
+}
+
+type jobPayload struct {
+	ParticipantID uuid.UUID `json:"participant_id,omitempty"`
+	Email         string    `json:"email,omitempty"`
+	InviteToken   string    `json:"invite_token,omitempty"`
+	OwnerName     string    `json:"owner_name,omitempty"`
+}
+
+// Enqueue persists job to the outbox, to be picked up by a Worker. It is safe
+// to call Enqueue more than once for the same IdempotencyKey: later calls are
+// no-ops.
+func (m MailPit) Enqueue(ctx context.Context, job Job) error {
+	payload, err := json.Marshal(jobPayload{
+		ParticipantID: job.ParticipantID,
+		Email:         job.Email,
+		InviteToken:   job.InviteToken,
+		OwnerName:     job.OwnerName,
+	})
+	if err != nil {
+		return fmt.Errorf("mailpit: failed to marshal outbox payload: %w", err)
+	}
+
+	_, err = m.store.EnqueueOutboxMessage(ctx, pgstore.EnqueueOutboxMessageParams{
+		IdempotencyKey: job.IdempotencyKey,
+		TripID:         job.TripID,
+		Kind:           job.Kind,
+		Payload:        payload,
+	})
+	if err != nil {
+		return fmt.Errorf("mailpit: failed to enqueue outbox message: %w", err)
+	}
+
+	return nil
+}
+
+// Worker pulls PENDING outbox rows and attempts delivery, rescheduling with
+// exponential backoff on failure.
+type Worker struct {
+	mailer    MailPit
+	batchSize int32
+	pollEvery time.Duration
+	// drainCtx bounds in-flight sends instead of ctx, so that Run's shutdown
+	// signal (which stops the poll loop) doesn't also cancel sends the
+	// shutdownGrace period exists to let finish. Defaults to ctx itself when
+	// unset, e.g. when a Worker is used directly in tooling rather than via
+	// MailPit.Run.
+	drainCtx context.Context
+}
+
+func NewWorker(mailer MailPit) Worker {
+	return Worker{mailer: mailer, batchSize: 50, pollEvery: 10 * time.Second}
+}
+
+// Run polls the outbox until ctx is canceled. A transient failure to lock
+// messages is logged and retried on the next tick rather than stopping the
+// worker for good.
+func (w Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.pollEvery)
+	defer ticker.Stop()
+
+	for {
+		w.runOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w Worker) runOnce(ctx context.Context) {
+	if err := w.mailer.store.RequeueStaleMailOutboxMessages(ctx, time.Now().Add(-staleProcessingAfter)); err != nil {
+		w.mailer.logger.Error("mailpit: failed to requeue stale outbox messages", zap.Error(err))
+	}
+
+	messages, err := w.mailer.store.LockPendingMailOutboxMessages(ctx, w.batchSize)
+	if err != nil {
+		w.mailer.logger.Error("mailpit: failed to lock pending outbox messages", zap.Error(err))
+		return
+	}
+
+	byTrip := make(map[uuid.UUID][]pgstore.OutboxMessage)
+	for _, message := range messages {
+		byTrip[message.TripID] = append(byTrip[message.TripID], message)
+	}
+
+	// Each trip's batch is delivered on its own goroutine, tracked by the
+	// mailer's WaitGroup so Run can drain outstanding sends on shutdown
+	// instead of abandoning them mid-flight.
+	for tripID, tripMessages := range byTrip {
+		w.mailer.wg.Add(1)
+		go func(tripID uuid.UUID, tripMessages []pgstore.OutboxMessage) {
+			defer w.mailer.wg.Done()
+			w.deliverTrip(w.sendContext(ctx), tripID, tripMessages)
+		}(tripID, tripMessages)
+	}
+}
+
+// sendContext returns the context a delivery goroutine should use: drainCtx
+// when Run set one (so in-flight sends survive the shutdown signal that
+// stops the poll loop), ctx otherwise.
+func (w Worker) sendContext(ctx context.Context) context.Context {
+	if w.drainCtx != nil {
+		return w.drainCtx
+	}
+	return ctx
+}
+
+// deliverTrip sends every message for a single trip over one SMTP
+// connection — dialed once up front and closed once the whole batch is
+// done, instead of per message — committing status per-message so a
+// failure on one participant doesn't block the rest.
+func (w Worker) deliverTrip(ctx context.Context, tripID uuid.UUID, messages []pgstore.OutboxMessage) {
+	client, err := mail.NewClient("mailpit", mail.WithTLSPortPolicy(mail.NoTLS), mail.WithPort(1025))
+	if err != nil {
+		for _, message := range messages {
+			w.reschedule(ctx, message, err)
+		}
+		return
+	}
+
+	trip, err := w.mailer.store.GetTrip(ctx, tripID)
+	if err != nil {
+		for _, message := range messages {
+			w.reschedule(ctx, message, err)
+		}
+		return
+	}
+
+	dialCtx, cancel := context.WithTimeout(ctx, w.mailer.sendDeadline)
+	err = client.DialWithContext(dialCtx)
+	cancel()
+	if err != nil {
+		for _, message := range messages {
+			w.reschedule(ctx, message, err)
+		}
+		return
+	}
+	defer func() {
+		if err := client.Close(); err != nil {
+			w.mailer.logger.Error("mailpit: failed to close SMTP connection", zap.Error(err), zap.String("trip_id", tripID.String()))
+		}
+	}()
+
+	for _, message := range messages {
+		msg, err := w.buildMessage(ctx, trip, message)
+		if err != nil {
+			w.reschedule(ctx, message, err)
+			continue
+		}
+
+		if err := client.Send(msg); err != nil {
+			w.reschedule(ctx, message, err)
+			continue
+		}
+
+		if err := w.mailer.store.MarkOutboxMessageSent(ctx, message.ID); err != nil {
+			continue
+		}
+	}
+}
+
+func (w Worker) buildMessage(ctx context.Context, trip pgstore.Trip, outboxMessage pgstore.OutboxMessage) (*mail.Msg, error) {
+	var payload jobPayload
+	if err := json.Unmarshal(outboxMessage.Payload, &payload); err != nil {
+		return nil, fmt.Errorf("mailpit: failed to unmarshal outbox payload: %w", err)
+	}
+
+	msg := mail.NewMsg()
+	if err := msg.From("mailpit@journey.com"); err != nil {
+		return nil, err
+	}
+
+	switch outboxMessage.Kind {
+	case JobKindConfirmTripOwner:
+		if err := msg.To(trip.OwnerEmail); err != nil {
+			return nil, err
+		}
+
+		lang, err := w.mailer.store.GetTripLanguage(ctx, trip.ID)
+		if err != nil {
+			lang = mailtmpl.DefaultLanguage
+		}
+
+		rendered, err := mailtmpl.Render(JobKindConfirmTripOwner, lang, mailtmpl.Data{
+			OwnerName:   trip.OwnerName,
+			Destination: trip.Destination,
+			ConfirmURL:  fmt.Sprintf("%s/trips/%s/confirm", w.mailer.publicBaseURL, trip.ID),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("mailpit: failed to render confirm_trip_owner email: %w", err)
+		}
+
+		msg.Subject(rendered.Subject)
+		msg.SetBodyString(mail.TypeTextPlain, rendered.Text)
+		msg.AddAlternativeString(mail.TypeTextHTML, rendered.HTML)
+	case JobKindTripConfirmed:
+		if err := msg.To(payload.Email); err != nil {
+			return nil, err
+		}
+
+		lang := mailtmpl.DefaultLanguage
+		if payload.ParticipantID != uuid.Nil {
+			if l, err := w.mailer.store.GetParticipantLanguage(ctx, payload.ParticipantID); err == nil {
+				lang = l
+			}
+		}
+
+		rendered, err := mailtmpl.Render(JobKindTripConfirmed, lang, mailtmpl.Data{
+			Destination: trip.Destination,
+			ConfirmURL:  fmt.Sprintf("%s/participants/%s/confirm", w.mailer.publicBaseURL, payload.ParticipantID),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("mailpit: failed to render trip_confirmed email: %w", err)
+		}
+
+		msg.Subject(rendered.Subject)
+		msg.SetBodyString(mail.TypeTextPlain, rendered.Text)
+		msg.AddAlternativeString(mail.TypeTextHTML, rendered.HTML)
+	case JobKindInvite:
+		if err := msg.To(payload.Email); err != nil {
+			return nil, err
+		}
+
+		lang, err := w.mailer.store.GetTripLanguage(ctx, trip.ID)
+		if err != nil {
+			lang = mailtmpl.DefaultLanguage
+		}
+
+		rendered, err := mailtmpl.Render(JobKindInvite, lang, mailtmpl.Data{
+			Destination: trip.Destination,
+			ConfirmURL:  fmt.Sprintf("%s/invites/%s", w.mailer.publicBaseURL, payload.InviteToken),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("mailpit: failed to render invite email: %w", err)
+		}
+
+		msg.Subject(rendered.Subject)
+		msg.SetBodyString(mail.TypeTextPlain, rendered.Text)
+		msg.AddAlternativeString(mail.TypeTextHTML, rendered.HTML)
+	case JobKindInviteExpiryReminder:
+		if err := msg.To(trip.OwnerEmail); err != nil {
+			return nil, err
+		}
+
+		lang, err := w.mailer.store.GetTripLanguage(ctx, trip.ID)
+		if err != nil {
+			lang = mailtmpl.DefaultLanguage
+		}
+
+		rendered, err := mailtmpl.Render(JobKindInviteExpiryReminder, lang, mailtmpl.Data{
+			OwnerName:    trip.OwnerName,
+			Destination:  trip.Destination,
+			InvitedEmail: payload.Email,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("mailpit: failed to render invite_expiry_reminder email: %w", err)
+		}
+
+		msg.Subject(rendered.Subject)
+		msg.SetBodyString(mail.TypeTextPlain, rendered.Text)
+		msg.AddAlternativeString(mail.TypeTextHTML, rendered.HTML)
+	default:
+		return nil, fmt.Errorf("mailpit: unknown outbox job kind %q", outboxMessage.Kind)
+	}
+
+	return msg, nil
+}
+
+func (w Worker) reschedule(ctx context.Context, message pgstore.OutboxMessage, sendErr error) {
+	attempt := int(message.Attempts)
+	if attempt >= len(backoffSchedule) {
+		_ = w.mailer.store.FailOutboxMessage(ctx, pgstore.FailOutboxMessageParams{ID: message.ID, LastError: sendErr.Error()})
+		return
+	}
+
+	_ = w.mailer.store.RescheduleOutboxMessage(ctx, pgstore.RescheduleOutboxMessageParams{
+		ID:            message.ID,
+		LastError:     sendErr.Error(),
+		NextAttemptAt: time.Now().Add(backoffSchedule[attempt]),
+	})
+}
+
+// FailedMessages returns outbox rows that exhausted their retries, for the
+// admin inspection endpoint.
+func (m MailPit) FailedMessages(ctx context.Context) ([]pgstore.OutboxMessage, error) {
+	return m.store.GetFailedOutboxMessages(ctx)
+}
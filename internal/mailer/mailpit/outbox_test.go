@@ -0,0 +1,88 @@
+package mailpit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"journey/internal/pgstore"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type fakeStore struct {
+	rescheduled []pgstore.RescheduleOutboxMessageParams
+	failed      []pgstore.FailOutboxMessageParams
+}
+
+func (f *fakeStore) GetTrip(context.Context, uuid.UUID) (pgstore.Trip, error) { return pgstore.Trip{}, nil }
+func (f *fakeStore) EnqueueOutboxMessage(context.Context, pgstore.EnqueueOutboxMessageParams) (uuid.UUID, error) {
+	return uuid.Nil, nil
+}
+func (f *fakeStore) LockPendingMailOutboxMessages(context.Context, int32) ([]pgstore.OutboxMessage, error) {
+	return nil, nil
+}
+func (f *fakeStore) RequeueStaleMailOutboxMessages(context.Context, time.Time) error { return nil }
+func (f *fakeStore) MarkOutboxMessageSent(context.Context, uuid.UUID) error          { return nil }
+func (f *fakeStore) RescheduleOutboxMessage(ctx context.Context, arg pgstore.RescheduleOutboxMessageParams) error {
+	f.rescheduled = append(f.rescheduled, arg)
+	return nil
+}
+func (f *fakeStore) FailOutboxMessage(ctx context.Context, arg pgstore.FailOutboxMessageParams) error {
+	f.failed = append(f.failed, arg)
+	return nil
+}
+func (f *fakeStore) GetFailedOutboxMessages(context.Context) ([]pgstore.OutboxMessage, error) {
+	return nil, nil
+}
+func (f *fakeStore) GetTripLanguage(context.Context, uuid.UUID) (string, error) { return "", nil }
+func (f *fakeStore) GetParticipantLanguage(context.Context, uuid.UUID) (string, error) {
+	return "", nil
+}
+
+func newTestWorker(store *fakeStore) Worker {
+	return Worker{mailer: MailPit{store: store, logger: zap.NewNop()}}
+}
+
+func TestRescheduleRetriesWithinBackoffSchedule(t *testing.T) {
+	for attempt := 0; attempt < len(backoffSchedule); attempt++ {
+		store := &fakeStore{}
+		w := newTestWorker(store)
+		message := pgstore.OutboxMessage{ID: uuid.New(), Attempts: int32(attempt)}
+
+		w.reschedule(context.Background(), message, errors.New("smtp down"))
+
+		if len(store.failed) != 0 {
+			t.Fatalf("attempt %d: expected no FailOutboxMessage call, got %d", attempt, len(store.failed))
+		}
+		if len(store.rescheduled) != 1 {
+			t.Fatalf("attempt %d: expected one RescheduleOutboxMessage call, got %d", attempt, len(store.rescheduled))
+		}
+		if store.rescheduled[0].ID != message.ID {
+			t.Errorf("attempt %d: rescheduled wrong message id", attempt)
+		}
+	}
+}
+
+func TestRescheduleFailsAfterExhaustingBackoffSchedule(t *testing.T) {
+	store := &fakeStore{}
+	w := newTestWorker(store)
+	message := pgstore.OutboxMessage{ID: uuid.New(), Attempts: int32(len(backoffSchedule))}
+
+	w.reschedule(context.Background(), message, errors.New("smtp down"))
+
+	if len(store.rescheduled) != 0 {
+		t.Fatalf("expected no RescheduleOutboxMessage call once attempts are exhausted, got %d", len(store.rescheduled))
+	}
+	if len(store.failed) != 1 {
+		t.Fatalf("expected one FailOutboxMessage call, got %d", len(store.failed))
+	}
+	if store.failed[0].ID != message.ID {
+		t.Error("failed wrong message id")
+	}
+	if store.failed[0].LastError != "smtp down" {
+		t.Errorf("LastError = %q, want %q", store.failed[0].LastError, "smtp down")
+	}
+}
@@ -0,0 +1,125 @@
+// Package mailtmpl renders localized email bodies from templates embedded
+// at build time, so mailpit no longer hardcodes message strings in Go code.
+package mailtmpl
+
+import (
+	"bytes"
+	"embed"
+	htmltemplate "html/template"
+	"path"
+	"strings"
+	texttemplate "text/template"
+)
+
+//go:embed templates/email
+var templatesFS embed.FS
+
+// DefaultLanguage is used whenever a recipient's preferred language has no
+// matching template.
+const DefaultLanguage = "pt-BR"
+
+// Data carries the variables every email template may reference.
+type Data struct {
+	OwnerName    string
+	Destination  string
+	ConfirmURL   string
+	InvitedEmail string
+}
+
+// Rendered holds the subject and the two alternative bodies of a rendered
+// email.
+type Rendered struct {
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// Render loads the named template for lang (falling back to
+// DefaultLanguage if lang has no template) and executes it with data,
+// producing the subject and both the text/plain and text/html bodies.
+func Render(name, lang string, data Data) (Rendered, error) {
+	subject, err := renderSubject(name, lang, data)
+	if err != nil {
+		return Rendered{}, err
+	}
+
+	html, err := renderHTML(name, lang, data)
+	if err != nil {
+		return Rendered{}, err
+	}
+
+	text, err := renderText(name, lang, data)
+	if err != nil {
+		return Rendered{}, err
+	}
+
+	return Rendered{Subject: subject, HTML: html, Text: text}, nil
+}
+
+func renderSubject(name, lang string, data Data) (string, error) {
+	file, err := resolveTemplate(name, lang, "subject.txt")
+	if err != nil {
+		return "", err
+	}
+
+	tmpl, err := texttemplate.ParseFS(templatesFS, file)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(buf.String()), nil
+}
+
+func renderHTML(name, lang string, data Data) (string, error) {
+	file, err := resolveTemplate(name, lang, "html")
+	if err != nil {
+		return "", err
+	}
+
+	tmpl, err := htmltemplate.ParseFS(templatesFS, file)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+func renderText(name, lang string, data Data) (string, error) {
+	file, err := resolveTemplate(name, lang, "txt")
+	if err != nil {
+		return "", err
+	}
+
+	tmpl, err := texttemplate.ParseFS(templatesFS, file)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// resolveTemplate returns the embedded path for name/lang.ext, falling back
+// to DefaultLanguage when lang has no template for name.
+func resolveTemplate(name, lang, ext string) (string, error) {
+	file := path.Join("templates", "email", name, lang+"."+ext)
+	if _, err := templatesFS.Open(file); err == nil {
+		return file, nil
+	}
+
+	return path.Join("templates", "email", name, DefaultLanguage+"."+ext), nil
+}
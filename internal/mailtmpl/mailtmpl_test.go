@@ -0,0 +1,48 @@
+package mailtmpl
+
+import "testing"
+
+func TestResolveTemplateFallsBackToDefaultLanguage(t *testing.T) {
+	file, err := resolveTemplate("confirm_trip_owner", "fr-FR", "txt")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "templates/email/confirm_trip_owner/" + DefaultLanguage + ".txt"
+	if file != want {
+		t.Errorf("resolveTemplate(fr-FR) = %q, want fallback %q", file, want)
+	}
+}
+
+func TestResolveTemplateUsesExactMatchWhenPresent(t *testing.T) {
+	file, err := resolveTemplate("confirm_trip_owner", "en", "txt")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "templates/email/confirm_trip_owner/en.txt"
+	if file != want {
+		t.Errorf("resolveTemplate(en) = %q, want %q", file, want)
+	}
+}
+
+func TestRenderEveryBuiltinTemplate(t *testing.T) {
+	data := Data{
+		OwnerName:    "Jane",
+		Destination:  "Florianópolis",
+		ConfirmURL:   "https://journey.example/confirm",
+		InvitedEmail: "guest@example.com",
+	}
+
+	for _, name := range []string{"confirm_trip_owner", "trip_confirmed", "invite", "invite_expiry_reminder"} {
+		for _, lang := range []string{"en", "pt-BR", "xx-XX"} {
+			rendered, err := Render(name, lang, data)
+			if err != nil {
+				t.Fatalf("Render(%q, %q) returned error: %v", name, lang, err)
+			}
+			if rendered.Subject == "" || rendered.HTML == "" || rendered.Text == "" {
+				t.Fatalf("Render(%q, %q) produced an empty part: %+v", name, lang, rendered)
+			}
+		}
+	}
+}
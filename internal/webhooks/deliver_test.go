@@ -0,0 +1,167 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"journey/internal/pgstore"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+type fakeWorkerStore struct {
+	subscription pgstore.WebhookSubscription
+	subErr       error
+	rescheduled  []pgstore.RescheduleWebhookDeliveryParams
+	failed       []pgstore.FailWebhookDeliveryParams
+	sent         []pgstore.MarkWebhookDeliverySentParams
+}
+
+func (f *fakeWorkerStore) GetWebhookSubscription(context.Context, uuid.UUID) (pgstore.WebhookSubscription, error) {
+	return f.subscription, f.subErr
+}
+func (f *fakeWorkerStore) LockPendingWebhookDeliveries(context.Context, int32) ([]pgstore.OutboxMessage, error) {
+	return nil, nil
+}
+func (f *fakeWorkerStore) RequeueStaleWebhookDeliveries(context.Context, time.Time) error { return nil }
+func (f *fakeWorkerStore) MarkWebhookDeliverySent(ctx context.Context, arg pgstore.MarkWebhookDeliverySentParams) error {
+	f.sent = append(f.sent, arg)
+	return nil
+}
+func (f *fakeWorkerStore) RescheduleWebhookDelivery(ctx context.Context, arg pgstore.RescheduleWebhookDeliveryParams) error {
+	f.rescheduled = append(f.rescheduled, arg)
+	return nil
+}
+func (f *fakeWorkerStore) FailWebhookDelivery(ctx context.Context, arg pgstore.FailWebhookDeliveryParams) error {
+	f.failed = append(f.failed, arg)
+	return nil
+}
+func (f *fakeWorkerStore) GetWebhookDelivery(context.Context, uuid.UUID) (pgstore.OutboxMessage, error) {
+	return pgstore.OutboxMessage{}, nil
+}
+func (f *fakeWorkerStore) RequeueWebhookDelivery(context.Context, uuid.UUID) error { return nil }
+func (f *fakeWorkerStore) ListFailedWebhookDeliveries(context.Context) ([]pgstore.OutboxMessage, error) {
+	return nil, nil
+}
+
+func newTestWorker(store *fakeWorkerStore) Worker {
+	return Worker{store: store, httpClient: &http.Client{Timeout: time.Second}, logger: zap.NewNop()}
+}
+
+func newTestDelivery(subscriptionID uuid.UUID, attempts int32) pgstore.OutboxMessage {
+	return pgstore.OutboxMessage{
+		ID:             uuid.New(),
+		Kind:           "trip.created",
+		Payload:        json.RawMessage(`{"event":"trip.created"}`),
+		Attempts:       attempts,
+		SubscriptionID: pgtype.UUID{Bytes: subscriptionID, Valid: true},
+	}
+}
+
+// TestDeliverMarksSentOn2xx covers the happy path: a 2xx response marks the
+// delivery sent and never touches reschedule/fail.
+func TestDeliverMarksSentOn2xx(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	subscriptionID := uuid.New()
+	store := &fakeWorkerStore{subscription: pgstore.WebhookSubscription{ID: subscriptionID, URL: server.URL, Secret: "secret"}}
+	w := newTestWorker(store)
+	delivery := newTestDelivery(subscriptionID, 0)
+
+	w.deliver(context.Background(), delivery)
+
+	if len(store.sent) != 1 {
+		t.Fatalf("expected one MarkWebhookDeliverySent call, got %d", len(store.sent))
+	}
+	if store.sent[0].ID != delivery.ID {
+		t.Error("marked the wrong delivery as sent")
+	}
+	if len(store.rescheduled) != 0 || len(store.failed) != 0 {
+		t.Error("expected no reschedule/fail calls on a 2xx response")
+	}
+}
+
+// TestDeliverReschedulesWithinBackoffSchedule covers a non-2xx response
+// while attempts remain: it should reschedule with the response code
+// recorded, not fail outright.
+func TestDeliverReschedulesWithinBackoffSchedule(t *testing.T) {
+	for attempt := 0; attempt < len(backoffSchedule); attempt++ {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+
+		subscriptionID := uuid.New()
+		store := &fakeWorkerStore{subscription: pgstore.WebhookSubscription{ID: subscriptionID, URL: server.URL, Secret: "secret"}}
+		w := newTestWorker(store)
+		delivery := newTestDelivery(subscriptionID, int32(attempt))
+
+		w.deliver(context.Background(), delivery)
+		server.Close()
+
+		if len(store.failed) != 0 {
+			t.Fatalf("attempt %d: expected no FailWebhookDelivery call, got %d", attempt, len(store.failed))
+		}
+		if len(store.rescheduled) != 1 {
+			t.Fatalf("attempt %d: expected one RescheduleWebhookDelivery call, got %d", attempt, len(store.rescheduled))
+		}
+		if store.rescheduled[0].ResponseCode.Int32 != http.StatusInternalServerError {
+			t.Errorf("attempt %d: ResponseCode = %d, want %d", attempt, store.rescheduled[0].ResponseCode.Int32, http.StatusInternalServerError)
+		}
+	}
+}
+
+// TestDeliverFailsAfterExhaustingBackoffSchedule covers the last attempt: a
+// non-2xx response after the backoff schedule is exhausted should fail the
+// delivery outright instead of rescheduling it again.
+func TestDeliverFailsAfterExhaustingBackoffSchedule(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	subscriptionID := uuid.New()
+	store := &fakeWorkerStore{subscription: pgstore.WebhookSubscription{ID: subscriptionID, URL: server.URL, Secret: "secret"}}
+	w := newTestWorker(store)
+	delivery := newTestDelivery(subscriptionID, int32(len(backoffSchedule)))
+
+	w.deliver(context.Background(), delivery)
+
+	if len(store.rescheduled) != 0 {
+		t.Fatalf("expected no RescheduleWebhookDelivery call once attempts are exhausted, got %d", len(store.rescheduled))
+	}
+	if len(store.failed) != 1 {
+		t.Fatalf("expected one FailWebhookDelivery call, got %d", len(store.failed))
+	}
+	if store.failed[0].ResponseCode.Int32 != http.StatusInternalServerError {
+		t.Errorf("ResponseCode = %d, want %d", store.failed[0].ResponseCode.Int32, http.StatusInternalServerError)
+	}
+}
+
+// TestDeliverReschedulesOnSubscriptionLookupFailure covers the error path
+// before an HTTP request is even attempted: a failed GetWebhookSubscription
+// call should reschedule (or fail, once exhausted) like any delivery error,
+// with no response code recorded.
+func TestDeliverReschedulesOnSubscriptionLookupFailure(t *testing.T) {
+	subscriptionID := uuid.New()
+	store := &fakeWorkerStore{subErr: context.DeadlineExceeded}
+	w := newTestWorker(store)
+	delivery := newTestDelivery(subscriptionID, 0)
+
+	w.deliver(context.Background(), delivery)
+
+	if len(store.rescheduled) != 1 {
+		t.Fatalf("expected one RescheduleWebhookDelivery call, got %d", len(store.rescheduled))
+	}
+	if store.rescheduled[0].ResponseCode.Valid {
+		t.Error("expected no response code recorded for a lookup failure")
+	}
+}
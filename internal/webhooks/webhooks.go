@@ -0,0 +1,97 @@
+// Package webhooks lets external systems subscribe to trip lifecycle
+// events and delivers them as signed HTTP POSTs, retrying on failure the
+// same way the mail outbox does.
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"journey/internal/pgstore"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Event names trip lifecycle events can publish. Keep this list in sync with
+// the handlers that actually call Publish — trips/{tripId}/links has no
+// handler yet, so there is no EventLinkCreated until one exists.
+const (
+	EventTripCreated          = "trip.created"
+	EventTripUpdated          = "trip.updated"
+	EventTripConfirmed        = "trip.confirmed"
+	EventParticipantInvited   = "participant.invited"
+	EventParticipantConfirmed = "participant.confirmed"
+	EventActivityCreated      = "activity.created"
+)
+
+type store interface {
+	ListActiveSubscriptionsForEvent(ctx context.Context, arg pgstore.ListActiveSubscriptionsForEventParams) ([]pgstore.WebhookSubscription, error)
+	EnqueueWebhookDelivery(ctx context.Context, arg pgstore.EnqueueWebhookDeliveryParams) (uuid.UUID, error)
+	CreateWebhookSubscription(ctx context.Context, arg pgstore.CreateWebhookSubscriptionParams) (uuid.UUID, error)
+	GetWebhookSubscription(ctx context.Context, id uuid.UUID) (pgstore.WebhookSubscription, error)
+	ListWebhookSubscriptionsForTrip(ctx context.Context, tripID uuid.UUID) ([]pgstore.WebhookSubscription, error)
+	DeleteWebhookSubscription(ctx context.Context, id uuid.UUID) error
+}
+
+// Publisher fans trip lifecycle events out to every matching subscription
+// by enqueuing a delivery for a background Worker to send.
+type Publisher struct {
+	store store
+}
+
+func NewPublisher(pool *pgxpool.Pool) Publisher {
+	return Publisher{pgstore.New(pool)}
+}
+
+// Publish enqueues a delivery for every active subscription (global or
+// scoped to trip) listening for event.
+func (p Publisher) Publish(ctx context.Context, event string, tripID uuid.UUID, data any) error {
+	subscriptions, err := p.store.ListActiveSubscriptionsForEvent(ctx, pgstore.ListActiveSubscriptionsForEventParams{TripID: tripID, Event: event})
+	if err != nil {
+		return fmt.Errorf("webhooks: failed to list subscriptions for event %q: %w", event, err)
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("webhooks: failed to marshal payload for event %q: %w", event, err)
+	}
+
+	for _, subscription := range subscriptions {
+		if _, err := p.store.EnqueueWebhookDelivery(ctx, pgstore.EnqueueWebhookDeliveryParams{
+			TripID:         tripID,
+			Event:          event,
+			SubscriptionID: subscription.ID,
+			Payload:        payload,
+		}); err != nil {
+			return fmt.Errorf("webhooks: failed to enqueue delivery for subscription %s: %w", subscription.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// Subscribe creates a new subscription. A nil tripID scopes it globally,
+// i.e. it receives the event for every trip.
+func (p Publisher) Subscribe(ctx context.Context, tripID uuid.UUID, url string, secret string, events []string) (uuid.UUID, error) {
+	var tripIDArg pgtype.UUID
+	if tripID != uuid.Nil {
+		tripIDArg = pgtype.UUID{Bytes: tripID, Valid: true}
+	}
+
+	return p.store.CreateWebhookSubscription(ctx, pgstore.CreateWebhookSubscriptionParams{
+		TripID: tripIDArg,
+		URL:    url,
+		Secret: secret,
+		Events: events,
+	})
+}
+
+func (p Publisher) ListForTrip(ctx context.Context, tripID uuid.UUID) ([]pgstore.WebhookSubscription, error) {
+	return p.store.ListWebhookSubscriptionsForTrip(ctx, tripID)
+}
+
+func (p Publisher) Unsubscribe(ctx context.Context, id uuid.UUID) error {
+	return p.store.DeleteWebhookSubscription(ctx, id)
+}
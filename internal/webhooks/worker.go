@@ -0,0 +1,162 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"journey/internal/pgstore"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// backoffSchedule mirrors the mail outbox's retry schedule.
+var backoffSchedule = []time.Duration{
+	30 * time.Second,
+	2 * time.Minute,
+	10 * time.Minute,
+}
+
+// staleProcessingAfter mirrors the mail outbox's recovery window: a
+// delivery claimed (status = 'processing') longer than this is assumed
+// abandoned by a dead worker and requeued on the next poll.
+const staleProcessingAfter = 5 * time.Minute
+
+type workerStore interface {
+	GetWebhookSubscription(ctx context.Context, id uuid.UUID) (pgstore.WebhookSubscription, error)
+	LockPendingWebhookDeliveries(ctx context.Context, limit int32) ([]pgstore.OutboxMessage, error)
+	RequeueStaleWebhookDeliveries(ctx context.Context, olderThan time.Time) error
+	MarkWebhookDeliverySent(ctx context.Context, arg pgstore.MarkWebhookDeliverySentParams) error
+	RescheduleWebhookDelivery(ctx context.Context, arg pgstore.RescheduleWebhookDeliveryParams) error
+	FailWebhookDelivery(ctx context.Context, arg pgstore.FailWebhookDeliveryParams) error
+	GetWebhookDelivery(ctx context.Context, id uuid.UUID) (pgstore.OutboxMessage, error)
+	RequeueWebhookDelivery(ctx context.Context, id uuid.UUID) error
+	ListFailedWebhookDeliveries(ctx context.Context) ([]pgstore.OutboxMessage, error)
+}
+
+// Worker polls PENDING webhook deliveries — rows in the same outbox table
+// the mail worker uses, filtered to the webhook channel — and POSTs them to
+// their subscription's URL, retrying with exponential backoff on failure.
+type Worker struct {
+	store      workerStore
+	httpClient *http.Client
+	logger     *zap.Logger
+	batchSize  int32
+	pollEvery  time.Duration
+}
+
+func NewWorker(pool *pgxpool.Pool, logger *zap.Logger) Worker {
+	return Worker{
+		store:      pgstore.New(pool),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		logger:     logger,
+		batchSize:  50,
+		pollEvery:  10 * time.Second,
+	}
+}
+
+// Run polls for pending deliveries until ctx is canceled. A transient
+// failure to lock deliveries is logged and retried on the next tick rather
+// than stopping the worker for good.
+func (w Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.pollEvery)
+	defer ticker.Stop()
+
+	for {
+		w.runOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w Worker) runOnce(ctx context.Context) {
+	if err := w.store.RequeueStaleWebhookDeliveries(ctx, time.Now().Add(-staleProcessingAfter)); err != nil {
+		w.logger.Error("webhooks: failed to requeue stale deliveries", zap.Error(err))
+	}
+
+	deliveries, err := w.store.LockPendingWebhookDeliveries(ctx, w.batchSize)
+	if err != nil {
+		w.logger.Error("webhooks: failed to lock pending deliveries", zap.Error(err))
+		return
+	}
+
+	for _, delivery := range deliveries {
+		w.deliver(ctx, delivery)
+	}
+}
+
+func (w Worker) deliver(ctx context.Context, delivery pgstore.OutboxMessage) {
+	subscription, err := w.store.GetWebhookSubscription(ctx, uuid.UUID(delivery.SubscriptionID.Bytes))
+	if err != nil {
+		w.reschedule(ctx, delivery, err, pgtype.Int4{})
+		return
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		w.reschedule(ctx, delivery, err, pgtype.Int4{})
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Journey-Event", delivery.Kind)
+	req.Header.Set("X-Journey-Signature", "sha256="+sign(subscription.Secret, delivery.Payload))
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		w.reschedule(ctx, delivery, err, pgtype.Int4{})
+		return
+	}
+	defer resp.Body.Close()
+
+	responseCode := pgtype.Int4{Int32: int32(resp.StatusCode), Valid: true}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		w.reschedule(ctx, delivery, fmt.Errorf("webhooks: received non-2xx status %d", resp.StatusCode), responseCode)
+		return
+	}
+
+	_ = w.store.MarkWebhookDeliverySent(ctx, pgstore.MarkWebhookDeliverySentParams{ID: delivery.ID, ResponseCode: responseCode.Int32})
+}
+
+func (w Worker) reschedule(ctx context.Context, delivery pgstore.OutboxMessage, deliverErr error, responseCode pgtype.Int4) {
+	attempt := int(delivery.Attempts)
+	if attempt >= len(backoffSchedule) {
+		_ = w.store.FailWebhookDelivery(ctx, pgstore.FailWebhookDeliveryParams{ID: delivery.ID, LastError: deliverErr.Error(), ResponseCode: responseCode})
+		return
+	}
+
+	_ = w.store.RescheduleWebhookDelivery(ctx, pgstore.RescheduleWebhookDeliveryParams{
+		ID:            delivery.ID,
+		LastError:     deliverErr.Error(),
+		ResponseCode:  responseCode,
+		NextAttemptAt: time.Now().Add(backoffSchedule[attempt]),
+	})
+}
+
+// Redeliver resets a delivery back to pending so the worker retries it
+// immediately, regardless of how many attempts it already exhausted.
+func (w Worker) Redeliver(ctx context.Context, deliveryID uuid.UUID) error {
+	return w.store.RequeueWebhookDelivery(ctx, deliveryID)
+}
+
+// FailedDeliveries returns deliveries that exhausted their retries, for the
+// admin inspection endpoint.
+func (w Worker) FailedDeliveries(ctx context.Context) ([]pgstore.OutboxMessage, error) {
+	return w.store.ListFailedWebhookDeliveries(ctx)
+}
+
+func sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,29 @@
+package webhooks
+
+import "testing"
+
+func TestSignIsDeterministicHMAC(t *testing.T) {
+	payload := []byte(`{"event":"trip.created"}`)
+
+	got := sign("secret", payload)
+	want := sign("secret", payload)
+	if got != want {
+		t.Fatalf("sign is not deterministic: %q != %q", got, want)
+	}
+
+	if len(got) != 64 {
+		t.Errorf("sign returned a %d-char string, want a 64-char hex-encoded sha256", len(got))
+	}
+}
+
+func TestSignDiffersByInput(t *testing.T) {
+	payload := []byte(`{"event":"trip.created"}`)
+
+	if sign("secret-a", payload) == sign("secret-b", payload) {
+		t.Error("sign produced the same output for different secrets")
+	}
+
+	if sign("secret", payload) == sign("secret", []byte(`{"event":"trip.updated"}`)) {
+		t.Error("sign produced the same output for different payloads")
+	}
+}